@@ -0,0 +1,139 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	pilotwriter "istio.io/istio/istioctl/pkg/writer/pilot"
+)
+
+var (
+	outputFormat  string
+	templateStr   string
+	watch         bool
+	watchInterval time.Duration
+
+	staleOnly         bool
+	xdsTypes          []string
+	versionConstraint string
+	proxyGlob         string
+)
+
+func statusCommand() *cobra.Command {
+	statusCmd := &cobra.Command{
+		Use:     "proxy-status [<pod-name[.namespace]>]",
+		Aliases: []string{"ps"},
+		Short:   "Retrieves the synchronization status of each Envoy in the mesh",
+		Long: `
+Retrieves last sent and last acknowledged xDS sync from Pilot to each Envoy in the mesh.
+`,
+		Example: `  # Retrieve sync status for all Envoys in a mesh
+  istioctl proxy-status
+
+  # Retrieve sync status for a single Envoy
+  istioctl proxy-status istio-egressgateway-59585c5b9c-ndc59.istio-system`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := parseStatusFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+			sw := pilotwriter.StatusWriter{
+				Writer:         cmd.OutOrStdout(),
+				Format:         format,
+				TemplateString: templateStr,
+				Filter: pilotwriter.Filter{
+					OnlyStale:              staleOnly,
+					XDSTypes:               xdsTypes,
+					IstioVersionConstraint: versionConstraint,
+					ProxyIDGlob:            proxyGlob,
+				},
+			}
+			if watch {
+				return sw.WatchAll(fetchPilotSyncStatus, watchInterval, interruptChan())
+			}
+			statuses, err := fetchPilotSyncStatus()
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				return sw.PrintSingle(statuses, args[0])
+			}
+			return sw.PrintAll(statuses)
+		},
+	}
+	statusCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table",
+		"Output format: one of table, json, yaml, template, or (with --watch) events")
+	statusCmd.PersistentFlags().StringVar(&templateStr, "template", "",
+		"Go template to render each proxy's status, used with --output=template")
+	statusCmd.PersistentFlags().BoolVar(&watch, "watch", false,
+		"Keep polling: redraw the table on each poll, or with --output=events print only what changed since the last poll")
+	statusCmd.PersistentFlags().DurationVar(&watchInterval, "watch-interval", 2*time.Second,
+		"Polling interval used with --watch")
+	statusCmd.PersistentFlags().BoolVar(&staleOnly, "stale", false,
+		"Only show proxies that are out of sync")
+	statusCmd.PersistentFlags().StringSliceVar(&xdsTypes, "xds", nil,
+		"Restrict --stale to these xDS types (cds, lds, eds, rds); defaults to all of them")
+	statusCmd.PersistentFlags().StringVar(&versionConstraint, "version", "",
+		"Only show proxies whose Istio version satisfies this constraint, e.g. '>=1.1'")
+	statusCmd.PersistentFlags().StringVar(&proxyGlob, "proxy", "",
+		"Only show proxies whose ID matches this glob")
+	return statusCmd
+}
+
+// interruptChan returns a channel that closes the first time the process
+// receives SIGINT, so a --watch loop can exit cleanly on Ctrl-C.
+func interruptChan() <-chan struct{} {
+	stop := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		<-sigs
+		close(stop)
+	}()
+	return stop
+}
+
+// fetchPilotSyncStatus polls every running Pilot's debug endpoint and returns
+// the raw /debug/syncz response, keyed by pod name.
+func fetchPilotSyncStatus() (map[string][]byte, error) {
+	kubeClient, err := clientExecFactory(kubeconfig, configContext)
+	if err != nil {
+		return nil, err
+	}
+	return kubeClient.AllPilotsDiscoveryDo(istioNamespace, "GET", "/debug/syncz", nil)
+}
+
+func parseStatusFormat(output string) (pilotwriter.Format, error) {
+	switch output {
+	case "", "table":
+		return pilotwriter.FormatTable, nil
+	case "json":
+		return pilotwriter.FormatJSON, nil
+	case "yaml":
+		return pilotwriter.FormatYAML, nil
+	case "template":
+		return pilotwriter.FormatTemplate, nil
+	case "events":
+		return pilotwriter.FormatEvents, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, expected table, json, yaml, template, or events", output)
+	}
+}
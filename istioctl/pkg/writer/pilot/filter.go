@@ -0,0 +1,195 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Filter narrows down the proxies PrintAll/PrintSingle render. A zero-value
+// Filter matches every proxy.
+type Filter struct {
+	// OnlyStale drops proxies where every xDS type in XDSTypes (or, if
+	// XDSTypes is empty, every xDS type) is synced.
+	OnlyStale bool
+	// XDSTypes restricts OnlyStale to these types: any of cds, lds, eds, rds
+	// (case-insensitive). Empty means all four.
+	XDSTypes []string
+	// IstioVersionConstraint keeps only proxies whose reported Istio version
+	// satisfies a constraint such as ">=1.1", "<=1.3", or "1.1" for exact match.
+	IstioVersionConstraint string
+	// ProxyIDGlob keeps only proxies whose ID matches this shell glob, e.g. "*.istio-system".
+	ProxyIDGlob string
+}
+
+func (f Filter) isZero() bool {
+	return !f.OnlyStale && len(f.XDSTypes) == 0 && f.IstioVersionConstraint == "" && f.ProxyIDGlob == ""
+}
+
+var xdsTypeByName = map[string]func(*writerStatus) xdsStatus{
+	"cds": func(w *writerStatus) xdsStatus { return w.CDS },
+	"lds": func(w *writerStatus) xdsStatus { return w.LDS },
+	"eds": func(w *writerStatus) xdsStatus { return w.EDS },
+	"rds": func(w *writerStatus) xdsStatus { return w.RDS },
+}
+
+func resolveXDSTypes(names []string) ([]func(*writerStatus) xdsStatus, error) {
+	if len(names) == 0 {
+		names = []string{"cds", "lds", "eds", "rds"}
+	}
+	fns := make([]func(*writerStatus) xdsStatus, 0, len(names))
+	for _, name := range names {
+		fn, ok := xdsTypeByName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown xDS type %q, expected one of cds, lds, eds, rds", name)
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+func allSynced(status *writerStatus, types []func(*writerStatus) xdsStatus) bool {
+	for _, xdsType := range types {
+		if xdsType(status).State != xdsStateSynced {
+			return false
+		}
+	}
+	return true
+}
+
+// applyFilter drops the proxies in statuses that don't match f, returning a
+// new slice. statuses is left untouched; a zero-value f returns it as is.
+func applyFilter(statuses []*writerStatus, f Filter) ([]*writerStatus, error) {
+	if f.isZero() {
+		return statuses, nil
+	}
+
+	types, err := resolveXDSTypes(f.XDSTypes)
+	if err != nil {
+		return nil, err
+	}
+	constraint, err := parseVersionConstraint(f.IstioVersionConstraint)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*writerStatus
+	for _, status := range statuses {
+		if f.ProxyIDGlob != "" {
+			matched, err := path.Match(f.ProxyIDGlob, status.ProxyID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy ID glob %q: %v", f.ProxyIDGlob, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		if constraint != nil && !constraint.matches(status.IstioVersion) {
+			continue
+		}
+		if f.OnlyStale && allSynced(status, types) {
+			continue
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
+
+// versionConstraint is a single comparison against a dotted version number,
+// e.g. ">=1.1". There's no need for anything richer than Istio's own
+// major.minor(.patch) versioning scheme.
+type versionConstraint struct {
+	op  string
+	ver []int
+}
+
+func parseVersionConstraint(s string) (*versionConstraint, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	op := "="
+	rest := s
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(s, candidate) {
+			op = candidate
+			rest = strings.TrimSpace(s[len(candidate):])
+			break
+		}
+	}
+	ver, err := parseVersionParts(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %v", s, err)
+	}
+	return &versionConstraint{op: op, ver: ver}, nil
+}
+
+func parseVersionParts(s string) ([]int, error) {
+	// Older proxies' fallback display version carries a trailing "*"; strip
+	// it so it still compares against a real constraint.
+	s = strings.TrimSuffix(s, "*")
+	fields := strings.Split(s, ".")
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+func compareVersionParts(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var av, bv int
+		if i < len(a) {
+			av = a[i]
+		}
+		if i < len(b) {
+			bv = b[i]
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (c *versionConstraint) matches(version string) bool {
+	ver, err := parseVersionParts(version)
+	if err != nil {
+		return false
+	}
+	cmp := compareVersionParts(ver, c.ver)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
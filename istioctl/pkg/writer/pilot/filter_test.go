@@ -0,0 +1,88 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
+	"istio.io/istio/tests/util"
+)
+
+func TestStatusWriter_PrintAll_Filter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "stale restricted to lds leaves only proxy3",
+			filter: Filter{OnlyStale: true, XDSTypes: []string{"lds"}},
+			want:   "testdata/staleLDS.txt",
+		},
+		{
+			name:   "proxy glob",
+			filter: Filter{ProxyIDGlob: "proxy2"},
+			want:   "testdata/singleStatus.txt",
+		},
+		{
+			name:   "version constraint excludes everything",
+			filter: Filter{IstioVersionConstraint: ">=2.0"},
+			want:   "testdata/empty.txt",
+		},
+		{
+			name:    "unknown xds type",
+			filter:  Filter{OnlyStale: true, XDSTypes: []string{"bogus"}},
+			wantErr: true,
+		},
+		{
+			name:    "unparseable version constraint",
+			filter:  Filter{IstioVersionConstraint: ">=not-a-version"},
+			wantErr: true,
+		},
+	}
+	input := map[string][]byte{}
+	for pilot, ss := range map[string][]v2.SyncStatus{
+		"pilot1": statusInput1(),
+		"pilot2": statusInput2(),
+		"pilot3": statusInput3(),
+	} {
+		b, _ := json.Marshal(ss)
+		input[pilot] = b
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &bytes.Buffer{}
+			sw := StatusWriter{Writer: got, Filter: tt.filter}
+			err := sw.PrintAll(input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			want, _ := ioutil.ReadFile(tt.want)
+			if err := util.Compare(got.Bytes(), want); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+
+	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
+)
+
+// Format selects how a StatusWriter renders the proxies it was given.
+type Format string
+
+const (
+	// FormatTable renders a tab-aligned table, the default.
+	FormatTable Format = "table"
+	// FormatJSON renders a JSON array of proxy statuses.
+	FormatJSON Format = "json"
+	// FormatYAML renders a YAML array of proxy statuses.
+	FormatYAML Format = "yaml"
+	// FormatTemplate renders each proxy status through TemplateString.
+	FormatTemplate Format = "template"
+	// FormatEvents renders WatchAll's diff stream as an append-only event
+	// log, one line per event, suitable for piping. PrintAll/PrintSingle
+	// treat it the same as FormatTable, since there's no diff to show.
+	FormatEvents Format = "events"
+)
+
+const (
+	xdsStateSynced  = "SYNCED"
+	xdsStateStale   = "STALE"
+	xdsStateNotSent = "NOT SENT"
+)
+
+// StatusWriter enables printing of sync status using multiple methods.
+type StatusWriter struct {
+	Writer io.Writer
+
+	// Format selects the rendering of PrintAll/PrintSingle. Defaults to FormatTable.
+	Format Format
+	// TemplateString is a text/template body used when Format is FormatTemplate.
+	// It is executed once per proxy, with access to the fields of writerStatus:
+	// Pilot, ProxyID, IstioVersion, and a CDS/LDS/EDS/RDS field for each xDS
+	// type, each carrying a derived State.
+	TemplateString string
+
+	// Filter narrows down the proxies PrintAll/PrintSingle render.
+	Filter Filter
+}
+
+// xdsStatus is the derived synchronization state for a single xDS type,
+// computed by comparing the last sent and last acknowledged nonce. The raw
+// nonces aren't kept around: they're only ever meaningful as this comparison.
+type xdsStatus struct {
+	State string `json:"state"`
+}
+
+func newXdsStatus(sent, acked string) xdsStatus {
+	switch {
+	case sent == "":
+		return xdsStatus{State: xdsStateNotSent}
+	case sent == acked:
+		return xdsStatus{State: xdsStateSynced}
+	default:
+		return xdsStatus{State: xdsStateStale}
+	}
+}
+
+// writerStatus is the per-proxy view rendered by every output format.
+type writerStatus struct {
+	Pilot        string `json:"pilot"`
+	ProxyID      string `json:"proxy"`
+	IstioVersion string `json:"istioVersion,omitempty"`
+
+	CDS xdsStatus `json:"cds"`
+	LDS xdsStatus `json:"lds"`
+	EDS xdsStatus `json:"eds"`
+	RDS xdsStatus `json:"rds"`
+}
+
+func newWriterStatus(pilot string, s v2.SyncStatus) *writerStatus {
+	version := s.IstioVersion
+	if version == "" && s.ProxyVersion != "" {
+		// Older proxies only reported their own version; mark it as such
+		// so the table doesn't imply it came from the control plane.
+		version = s.ProxyVersion + "*"
+	}
+	return &writerStatus{
+		Pilot:        pilot,
+		ProxyID:      s.ProxyID,
+		IstioVersion: version,
+		CDS:          newXdsStatus(s.ClusterSent, s.ClusterAcked),
+		LDS:          newXdsStatus(s.ListenerSent, s.ListenerAcked),
+		EDS:          newXdsStatus(s.EndpointSent, s.EndpointAcked),
+		RDS:          newXdsStatus(s.RouteSent, s.RouteAcked),
+	}
+}
+
+// PrintAll takes a map of Pilot syncz responses and outputs, using the
+// configured Format, every proxy that passes Filter.
+func (s *StatusWriter) PrintAll(statuses map[string][]byte) error {
+	w, err := s.parseStatuses(statuses)
+	if err != nil {
+		return err
+	}
+	w, err = applyFilter(w, s.Filter)
+	if err != nil {
+		return err
+	}
+	return s.print(w)
+}
+
+// PrintSingle takes a map of Pilot syncz responses and outputs, using the
+// configured Format, only the proxies whose ID is prefixed by proxyID and
+// that pass Filter.
+func (s *StatusWriter) PrintSingle(statuses map[string][]byte, proxyID string) error {
+	w, err := s.parseStatuses(statuses)
+	if err != nil {
+		return err
+	}
+	w, err = applyFilter(w, s.Filter)
+	if err != nil {
+		return err
+	}
+	var filtered []*writerStatus
+	for _, status := range w {
+		if strings.HasPrefix(status.ProxyID, proxyID) {
+			filtered = append(filtered, status)
+		}
+	}
+	return s.print(filtered)
+}
+
+func (s *StatusWriter) parseStatuses(statuses map[string][]byte) ([]*writerStatus, error) {
+	var full []*writerStatus
+	for pilot, status := range statuses {
+		var ss []v2.SyncStatus
+		if err := json.Unmarshal(status, &ss); err != nil {
+			return nil, fmt.Errorf("parsing proxy-status from %s: %v", pilot, err)
+		}
+		for _, s := range ss {
+			full = append(full, newWriterStatus(pilot, s))
+		}
+	}
+	sort.Slice(full, func(i, j int) bool {
+		return full[i].ProxyID < full[j].ProxyID
+	})
+	return full, nil
+}
+
+func (s *StatusWriter) print(statuses []*writerStatus) error {
+	if statuses == nil {
+		// Render "no proxies" as an empty array rather than null.
+		statuses = []*writerStatus{}
+	}
+	switch s.Format {
+	case FormatJSON:
+		return s.printJSON(statuses)
+	case FormatYAML:
+		return s.printYAML(statuses)
+	case FormatTemplate:
+		return s.printTemplate(statuses)
+	case FormatTable, FormatEvents, "":
+		return s.printTable(statuses)
+	default:
+		return fmt.Errorf("unknown proxy-status format %q", s.Format)
+	}
+}
+
+func (s *StatusWriter) printTable(statuses []*writerStatus) error {
+	w := new(tabwriter.Writer).Init(s.Writer, 0, 8, 5, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCDS\tLDS\tEDS\tRDS\tPILOT\tVERSION")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			status.ProxyID, status.CDS.State, status.LDS.State, status.EDS.State, status.RDS.State,
+			status.Pilot, status.IstioVersion)
+	}
+	return w.Flush()
+}
+
+func (s *StatusWriter) printJSON(statuses []*writerStatus) error {
+	b, err := json.MarshalIndent(statuses, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(s.Writer, string(b))
+	return err
+}
+
+func (s *StatusWriter) printYAML(statuses []*writerStatus) error {
+	b, err := yaml.Marshal(statuses)
+	if err != nil {
+		return err
+	}
+	_, err = s.Writer.Write(b)
+	return err
+}
+
+func (s *StatusWriter) printTemplate(statuses []*writerStatus) error {
+	t, err := template.New("proxy-status").Parse(s.TemplateString)
+	if err != nil {
+		return fmt.Errorf("parsing --template: %v", err)
+	}
+	for _, status := range statuses {
+		if err := t.Execute(s.Writer, status); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(s.Writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
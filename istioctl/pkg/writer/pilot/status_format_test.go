@@ -0,0 +1,89 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/tests/util"
+)
+
+func TestStatusWriter_PrintAll_JSON(t *testing.T) {
+	got := &bytes.Buffer{}
+	sw := StatusWriter{Writer: got, Format: FormatJSON}
+	err := sw.PrintAll(multiPilotInput())
+	assert.NoError(t, err)
+	want, _ := ioutil.ReadFile("testdata/multiStatusMultiPilot.json")
+	if err := util.Compare(got.Bytes(), want); err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestStatusWriter_PrintAll_YAML(t *testing.T) {
+	got := &bytes.Buffer{}
+	sw := StatusWriter{Writer: got, Format: FormatYAML}
+	err := sw.PrintAll(multiPilotInput())
+	assert.NoError(t, err)
+
+	var decoded []writerStatus
+	assert.NoError(t, yaml.Unmarshal(got.Bytes(), &decoded))
+	if assert.Len(t, decoded, 3) {
+		assert.Equal(t, "proxy1", decoded[0].ProxyID)
+		assert.Equal(t, "proxy2", decoded[1].ProxyID)
+		assert.Equal(t, "proxy3", decoded[2].ProxyID)
+		assert.Equal(t, xdsStateStale, decoded[1].CDS.State)
+		assert.Equal(t, xdsStateSynced, decoded[1].RDS.State)
+	}
+}
+
+func TestStatusWriter_PrintAll_Template(t *testing.T) {
+	got := &bytes.Buffer{}
+	sw := StatusWriter{
+		Writer:         got,
+		Format:         FormatTemplate,
+		TemplateString: "{{.ProxyID}} {{.CDS.State}}",
+	}
+	b, _ := json.Marshal(statusInput2())
+	err := sw.PrintAll(map[string][]byte{"pilot2": b})
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy2 STALE\n", got.String())
+}
+
+func TestStatusWriter_PrintAll_UnknownFormat(t *testing.T) {
+	got := &bytes.Buffer{}
+	sw := StatusWriter{Writer: got, Format: "bogus"}
+	b, _ := json.Marshal(statusInput1())
+	err := sw.PrintAll(map[string][]byte{"pilot1": b})
+	assert.Error(t, err)
+}
+
+func multiPilotInput() map[string][]byte {
+	raw := map[string][]byte{}
+	for pilot, ss := range map[string]interface{}{
+		"pilot1": statusInput1(),
+		"pilot2": statusInput2(),
+		"pilot3": statusInput3(),
+	} {
+		b, _ := json.Marshal(ss)
+		raw[pilot] = b
+	}
+	return raw
+}
@@ -0,0 +1,160 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	eventProxyAdded    = "added"
+	eventProxyRemoved  = "removed"
+	eventXdsTransition = "transition"
+	clearScreenAndHome = "\033[H\033[2J"
+)
+
+// watchEvent is a single change observed between two consecutive polls of
+// WatchAll: a proxy appearing, disappearing, or one of its xDS types moving
+// between synced/stale/not-sent.
+type watchEvent struct {
+	Kind    string
+	ProxyID string
+	XDSType string
+	From    string
+	To      string
+}
+
+func (e watchEvent) String() string {
+	switch e.Kind {
+	case eventProxyAdded:
+		return fmt.Sprintf("+ %s", e.ProxyID)
+	case eventProxyRemoved:
+		return fmt.Sprintf("- %s", e.ProxyID)
+	default:
+		return fmt.Sprintf("~ %s %s %s->%s", e.ProxyID, e.XDSType, e.From, e.To)
+	}
+}
+
+// WatchAll polls input at the given interval until stop is closed, emitting
+// a diff of the sync status of every proxy between each poll. Format selects
+// how the diff is rendered: FormatEvents appends one line per event, suitable
+// for piping; any other format clears the screen and redraws the full table.
+func (s *StatusWriter) WatchAll(input func() (map[string][]byte, error), interval time.Duration, stop <-chan struct{}) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := map[string]*writerStatus{}
+	first := true
+	for {
+		raw, err := input()
+		if err != nil {
+			return err
+		}
+		cur, err := s.parseStatuses(raw)
+		if err != nil {
+			return err
+		}
+		cur, err = applyFilter(cur, s.Filter)
+		if err != nil {
+			return err
+		}
+		curByID := make(map[string]*writerStatus, len(cur))
+		for _, st := range cur {
+			curByID[st.ProxyID] = st
+		}
+
+		if err := s.renderWatch(cur, diffStatus(prev, curByID), first); err != nil {
+			return err
+		}
+
+		prev = curByID
+		first = false
+		// Check stop on its own first: if both stop and a tick are ready, a
+		// single select would pick one at random.
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *StatusWriter) renderWatch(cur []*writerStatus, events []watchEvent, first bool) error {
+	if s.Format == FormatEvents {
+		for _, e := range events {
+			if _, err := fmt.Fprintln(s.Writer, e.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !first {
+		if _, err := fmt.Fprint(s.Writer, clearScreenAndHome); err != nil {
+			return err
+		}
+	}
+	return s.printTable(cur)
+}
+
+// diffStatus compares the previous and current poll, keyed by proxy ID, and
+// returns the events needed to explain one in terms of the other: additions,
+// removals, and per-xDS-type state transitions for proxies present in both.
+func diffStatus(prev, cur map[string]*writerStatus) []watchEvent {
+	var events []watchEvent
+	for id, st := range cur {
+		old, ok := prev[id]
+		if !ok {
+			events = append(events, watchEvent{Kind: eventProxyAdded, ProxyID: id})
+			continue
+		}
+		events = append(events, xdsTransition(id, "CDS", old.CDS, st.CDS)...)
+		events = append(events, xdsTransition(id, "LDS", old.LDS, st.LDS)...)
+		events = append(events, xdsTransition(id, "EDS", old.EDS, st.EDS)...)
+		events = append(events, xdsTransition(id, "RDS", old.RDS, st.RDS)...)
+	}
+	for id := range prev {
+		if _, ok := cur[id]; !ok {
+			events = append(events, watchEvent{Kind: eventProxyRemoved, ProxyID: id})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].ProxyID != events[j].ProxyID {
+			return events[i].ProxyID < events[j].ProxyID
+		}
+		return events[i].XDSType < events[j].XDSType
+	})
+	return events
+}
+
+func xdsTransition(proxyID, xdsType string, from, to xdsStatus) []watchEvent {
+	if from.State == to.State {
+		return nil
+	}
+	return []watchEvent{{
+		Kind:    eventXdsTransition,
+		ProxyID: proxyID,
+		XDSType: xdsType,
+		From:    from.State,
+		To:      to.State,
+	}}
+}
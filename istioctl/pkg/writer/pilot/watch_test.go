@@ -0,0 +1,134 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
+)
+
+// watchTick returns the raw syncz payload for a single poll of WatchAll.
+func watchTick(pilot string, ss ...v2.SyncStatus) map[string][]byte {
+	if len(ss) == 0 {
+		return map[string][]byte{}
+	}
+	b, _ := json.Marshal(ss)
+	return map[string][]byte{pilot: b}
+}
+
+// runWatch feeds sequence into WatchAll one poll at a time, closing stop as
+// soon as the last element has been served, and returns the rendered output.
+func runWatch(t *testing.T, format Format, sequence []map[string][]byte) string {
+	t.Helper()
+	stop := make(chan struct{})
+	calls := 0
+	input := func() (map[string][]byte, error) {
+		tick := sequence[calls]
+		calls++
+		if calls == len(sequence) {
+			close(stop)
+		}
+		return tick, nil
+	}
+
+	got := &bytes.Buffer{}
+	sw := StatusWriter{Writer: got, Format: format}
+	// A short interval keeps the test fast; input() closes stop once the
+	// sequence is exhausted so WatchAll always terminates deterministically.
+	err := sw.WatchAll(input, time.Millisecond, stop)
+	assert.NoError(t, err)
+	assert.Equal(t, len(sequence), calls)
+	return got.String()
+}
+
+func TestStatusWriter_WatchAll_Events(t *testing.T) {
+	sequence := []map[string][]byte{
+		watchTick("pilot1", v2.SyncStatus{
+			ProxyID: "proxy1", IstioVersion: "1.1",
+			ClusterSent: "n1", ClusterAcked: "n1",
+		}),
+		watchTick("pilot1", v2.SyncStatus{
+			ProxyID: "proxy1", IstioVersion: "1.1",
+			ClusterSent: "n1", ClusterAcked: "n0",
+		}),
+		watchTick("pilot1"),
+	}
+
+	got := runWatch(t, FormatEvents, sequence)
+	assert.Equal(t, "+ proxy1\n~ proxy1 CDS SYNCED->STALE\n- proxy1\n", got)
+}
+
+func TestStatusWriter_WatchAll_NoChange(t *testing.T) {
+	status := v2.SyncStatus{ProxyID: "proxy1", IstioVersion: "1.1", ClusterSent: "n1", ClusterAcked: "n1"}
+	sequence := []map[string][]byte{
+		watchTick("pilot1", status),
+		watchTick("pilot1", status),
+	}
+
+	got := runWatch(t, FormatEvents, sequence)
+	assert.Equal(t, "+ proxy1\n", got)
+}
+
+func TestStatusWriter_WatchAll_TableRepaint(t *testing.T) {
+	status := v2.SyncStatus{ProxyID: "proxy1", IstioVersion: "1.1", ClusterSent: "n1", ClusterAcked: "n1"}
+	sequence := []map[string][]byte{
+		watchTick("pilot1", status),
+		watchTick("pilot1", status),
+	}
+
+	got := runWatch(t, FormatTable, sequence)
+	assert.Equal(t, 1, strings.Count(got, clearScreenAndHome))
+	assert.Equal(t, 2, strings.Count(got, "proxy1"))
+}
+
+func TestStatusWriter_WatchAll_Filter(t *testing.T) {
+	sequence := []map[string][]byte{
+		watchTick("pilot1",
+			v2.SyncStatus{ProxyID: "proxy1", IstioVersion: "1.1", ClusterSent: "n1", ClusterAcked: "n1"},
+			v2.SyncStatus{ProxyID: "proxy2", IstioVersion: "1.1", ClusterSent: "n1", ClusterAcked: "n0"},
+		),
+	}
+
+	stop := make(chan struct{})
+	calls := 0
+	input := func() (map[string][]byte, error) {
+		tick := sequence[calls]
+		calls++
+		close(stop)
+		return tick, nil
+	}
+
+	got := &bytes.Buffer{}
+	sw := StatusWriter{Writer: got, Format: FormatEvents, Filter: Filter{OnlyStale: true, XDSTypes: []string{"cds"}}}
+	err := sw.WatchAll(input, time.Millisecond, stop)
+	assert.NoError(t, err)
+	assert.Equal(t, "+ proxy2\n", got.String())
+}
+
+func TestStatusWriter_WatchAll_Error(t *testing.T) {
+	boom := assert.AnError
+	got := &bytes.Buffer{}
+	sw := StatusWriter{Writer: got}
+	stop := make(chan struct{})
+	err := sw.WatchAll(func() (map[string][]byte, error) { return nil, boom }, time.Millisecond, stop)
+	assert.Equal(t, boom, err)
+}
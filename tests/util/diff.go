@@ -0,0 +1,47 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package util holds small test helpers shared across test packages.
+package util
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Compare returns an error describing the first line at which content and
+// expected diverge, or nil if they are identical.
+func Compare(content, expected []byte) error {
+	if bytes.Equal(content, expected) {
+		return nil
+	}
+
+	gotLines := bytes.Split(content, []byte("\n"))
+	wantLines := bytes.Split(expected, []byte("\n"))
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var got, want string
+		if i < len(gotLines) {
+			got = string(gotLines[i])
+		}
+		if i < len(wantLines) {
+			want = string(wantLines[i])
+		}
+		if got != want {
+			return fmt.Errorf("mismatch at line %d:\n got:  %q\n want: %q", i+1, got, want)
+		}
+	}
+	// Every line matched but the bytes differ, so one side has extra
+	// trailing empty lines.
+	return fmt.Errorf("mismatch: got %d lines, want %d", len(gotLines), len(wantLines))
+}